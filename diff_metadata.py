@@ -0,0 +1,54 @@
+import argparse
+import json
+import sys
+
+parser = argparse.ArgumentParser(
+    description="show what changed between two metadata.json files of a book."
+)
+parser.add_argument("old")
+parser.add_argument("new")
+
+
+def diff_metadata(old, new):
+    # Field-level changes between two metadata dicts; tags are compared as a
+    # set so reordering alone is not reported
+    changes = []
+    for field in sorted(set(old) | set(new)):
+        if field == "tags":
+            continue
+        if old.get(field) != new.get(field):
+            changes.append(
+                {"field": field, "old": old.get(field), "new": new.get(field)}
+            )
+
+    old_tags = old.get("tags", [])
+    new_tags = new.get("tags", [])
+    added = [tag for tag in new_tags if tag not in old_tags]
+    removed = [tag for tag in old_tags if tag not in new_tags]
+    if added or removed:
+        changes.append({"field": "tags", "added": added, "removed": removed})
+
+    return changes
+
+
+def format_change(change):
+    if change["field"] == "tags":
+        parts = ["+" + tag for tag in change["added"]]
+        parts += ["-" + tag for tag in change["removed"]]
+        return "tags: " + " ".join(parts)
+
+    return "%s: %r -> %r" % (change["field"], change["old"], change["new"])
+
+
+if __name__ == "__main__":
+    args = parser.parse_args()
+    with open(args.old, "r") as file:
+        old = json.load(file)
+    with open(args.new, "r") as file:
+        new = json.load(file)
+
+    changes = diff_metadata(old, new)
+    for change in changes:
+        print(format_change(change))
+    # exit like diff(1): 1 when the files differ
+    sys.exit(1 if changes else 0)
@@ -0,0 +1,53 @@
+import argparse
+import csv
+import json
+import os
+
+parser = argparse.ArgumentParser(
+    description="write a CSV or JSON catalog of every downloaded book under a folder."
+)
+parser.add_argument("root", help="folder holding one directory per book")
+parser.add_argument("out", help="catalog file, .json for JSON, CSV otherwise")
+
+FIELDS = ["title", "author", "level", "tags", "slug", "path"]
+
+
+def build_catalog(root):
+    # One row per metadata.json; the directory holding it is the book slug
+    catalog = []
+    for dirpath, dirnames, filenames in os.walk(root):
+        dirnames.sort()
+        if "metadata.json" not in filenames:
+            continue
+        with open(os.path.join(dirpath, "metadata.json"), "r") as file:
+            data = json.load(file)
+        catalog.append(
+            {
+                "title": data.get("title", ""),
+                "author": data.get("author", ""),
+                "level": data.get("level", ""),
+                "tags": data.get("tags", []),
+                "slug": os.path.basename(dirpath),
+                "path": os.path.relpath(dirpath, root),
+            }
+        )
+
+    return catalog
+
+
+def write_catalog(catalog, out):
+    with open(out, "w", newline="") as file:
+        if out.endswith(".json"):
+            json.dump(catalog, file, indent=4)
+            return
+        writer = csv.DictWriter(file, fieldnames=FIELDS)
+        writer.writeheader()
+        for row in catalog:
+            writer.writerow(dict(row, tags=";".join(row["tags"])))
+
+
+if __name__ == "__main__":
+    args = parser.parse_args()
+    catalog = build_catalog(args.root)
+    write_catalog(catalog, args.out)
+    print(str(len(catalog)) + " books written to " + args.out)
@@ -1,14 +1,39 @@
 import argparse
 import json
+import re
+import sys
+import xml.etree.ElementTree as ET
+import zipfile
 
 import requests
 from bs4 import BeautifulSoup
 
-parser = argparse.ArgumentParser(description="")
+parser = argparse.ArgumentParser(
+    description="print the metadata of an english-e-reader.net book as JSON. "
+    "Exits with an error when the book page does not return 200."
+)
 parser.add_argument("-t", "--title")
-
-
-args = parser.parse_args()
+parser.add_argument(
+    "--require-title",
+    action="store_true",
+    help="exit with an error when the page has no title",
+)
+parser.add_argument(
+    "--tag-aliases",
+    help="JSON file mapping site tags to preferred tags",
+)
+parser.add_argument(
+    "--extra",
+    action="append",
+    default=[],
+    metavar="KEY=VALUE",
+    help="extra field stored under the 'extra' object, may be repeated",
+)
+parser.add_argument(
+    "--from-epub",
+    metavar="EPUB",
+    help="read the metadata from a downloaded epub instead of the site",
+)
 
 
 def extract_english_level_revised(html_content):
@@ -37,6 +62,67 @@ def extract_tags_corrected(html_content):
     return tags
 
 
+def extract_count(html_content, label):
+    # The page carries statistics lines such as "words: 5,400"; 0 when absent
+    if isinstance(html_content, bytes):
+        html_content = html_content.decode("utf-8", errors="ignore")
+    pattern = r"^" + re.escape(label) + r": (\d[\d,]*)"
+    match = re.search(pattern, html_content, re.MULTILINE)
+    if match:
+        return int(match.group(1).replace(",", ""))
+
+    return 0
+
+
+def extract_word_count(html_content):
+    return extract_count(html_content, "words")
+
+
+def extract_headwords(html_content):
+    return extract_count(html_content, "headwords")
+
+
+def normalize_tags(tags, aliases):
+    # Map free-form site tags ("sci-fi") onto the preferred vocabulary
+    # ("science fiction"), keeping unknown tags and dropping duplicates
+    normalized = []
+    seen = set()
+    for tag in tags:
+        tag = aliases.get(tag.lower(), tag)
+        if tag.lower() not in seen:
+            seen.add(tag.lower())
+            normalized.append(tag)
+
+    return normalized
+
+
+def load_tag_aliases(path):
+    # Read a {"alias": "preferred tag"} JSON object, keyed case-insensitively
+    with open(path, "r") as file:
+        aliases = json.load(file)
+    if not isinstance(aliases, dict) or not all(
+        isinstance(k, str) and isinstance(v, str) for k, v in aliases.items()
+    ):
+        raise ValueError("must be a JSON object mapping tags to tags")
+
+    return {k.lower(): v for k, v in aliases.items()}
+
+
+def parse_extra(pairs):
+    # Custom fields go under their own "extra" object so they can never
+    # clash with the parsed ones
+    extra = {}
+    for pair in pairs:
+        key, sep, value = pair.partition("=")
+        if not sep or not key:
+            raise ValueError(repr(pair) + " is not KEY=VALUE")
+        if key in extra:
+            raise ValueError(repr(key) + " given twice")
+        extra[key] = value
+
+    return extra
+
+
 def find_english_level_in_html(html_content):
     # Dictionary of English levels to search for
     levels_to_find = {
@@ -62,7 +148,7 @@ def find_english_level_in_html(html_content):
 
 
 # Function to extract information from an HTML file
-def extract_info_from_html(html_content):
+def extract_info_from_html(html_content, tag_aliases=None):
     # Parsing the HTML content
     soup = BeautifulSoup(html_content, "html.parser")
 
@@ -87,6 +173,10 @@ def extract_info_from_html(html_content):
     )
     level = find_english_level_in_html(html_content)
     tags = extract_tags_corrected(html_content)
+    if tag_aliases:
+        tags = normalize_tags(tags, tag_aliases)
+    word_count = extract_word_count(html_content)
+    headwords = extract_headwords(html_content)
 
     return {
         "title": title,
@@ -94,26 +184,112 @@ def extract_info_from_html(html_content):
         "author": author,
         "description": description,
         "tags": tags,
+        "word_count": word_count,
+        "headwords": headwords,
     }
 
 
+# Dictionary mapping the original levels to the new levels
+LEVEL_MAPPING = {
+    "A1 Starter": "Beginner 1",
+    "A2 Elementary": "Beginner 2",
+    "B1 Pre-Intermediate": "Intermediate 1",
+    "B1+ Intermediate": "Intermediate 1",
+    "B2 Intermediate-Plus": "Intermediate 2",
+    "B2+ Upper-Intermediate": "Intermediate 2",
+    "C1 Advanced": "Advanced 1",
+    "C2 Unabridged": "Advanced 2",
+}
+
+
+def level_mapping():
+    # A copy, so callers can't change how map_english_levels behaves
+    return dict(LEVEL_MAPPING)
+
+
 def map_english_levels(original_level):
-    # Dictionary mapping the original levels to the new levels
-    level_mapping = {
-        "A1 Starter": "Beginner 1",
-        "A2 Elementary": "Beginner 2",
-        "B1 Pre-Intermediate": "Intermediate 1",
-        "B1+ Intermediate": "Intermediate 1",
-        "B2 Intermediate-Plus": "Intermediate 2",
-        "B2+ Upper-Intermediate": "Intermediate 2",
-        "C1 Advanced": "Advanced 1",
-        "C2 Unabridged": "Advanced 2",
+    # Return the corresponding new level
+    return LEVEL_MAPPING.get(original_level, "Unknown Level")
+
+
+# What a missing, truncated or malformed epub raises from metadata_from_epub
+EPUB_ERRORS = (OSError, KeyError, ValueError, zipfile.BadZipFile, ET.ParseError)
+
+
+def metadata_from_epub(path):
+    # META-INF/container.xml points at the OPF, whose <metadata> holds the
+    # Dublin Core fields
+    ns = {
+        "c": "urn:oasis:names:tc:opendocument:xmlns:container",
+        "opf": "http://www.idpf.org/2007/opf",
+        "dc": "http://purl.org/dc/elements/1.1/",
     }
+    with zipfile.ZipFile(path) as epub_file:
+        container = ET.fromstring(epub_file.read("META-INF/container.xml"))
+        rootfile = container.find("c:rootfiles/c:rootfile", ns)
+        if rootfile is None:
+            raise ValueError("container.xml has no rootfile")
+        opf = ET.fromstring(epub_file.read(rootfile.get("full-path")))
 
-    # Return the corresponding new level
-    return level_mapping.get(original_level, "Unknown Level")
+    metadata = opf.find("opf:metadata", ns)
+    if metadata is None:
+        raise ValueError("OPF has no metadata")
+
+    def text(field):
+        element = metadata.find("dc:" + field, ns)
+        return element.text.strip() if element is not None and element.text else ""
+
+    return {
+        "title": text("title"),
+        "author": text("creator"),
+        "language": text("language"),
+        "description": text("description"),
+        "tags": [
+            subject.text.strip()
+            for subject in metadata.findall("dc:subject", ns)
+            if subject.text
+        ],
+    }
+
+
+def has_title(info):
+    # An empty title almost always means the page didn't parse or isn't a book
+    return info["title"] not in ("", "Title not found")
+
+
+def main(argv=None):
+    args = parser.parse_args(argv)
+    aliases = {}
+    if args.tag_aliases:
+        try:
+            aliases = load_tag_aliases(args.tag_aliases)
+        except (OSError, ValueError) as e:
+            parser.error("--tag-aliases " + args.tag_aliases + ": " + str(e))
+    try:
+        extra = parse_extra(args.extra)
+    except ValueError as e:
+        parser.error("--extra " + str(e))
+    if args.from_epub:
+        try:
+            info_c = metadata_from_epub(args.from_epub)
+        except EPUB_ERRORS as e:
+            sys.exit("cannot read metadata from " + args.from_epub + ": " + str(e))
+        if aliases:
+            info_c["tags"] = normalize_tags(info_c["tags"], aliases)
+    else:
+        r = requests.get("https://english-e-reader.net/book/" + args.title)
+        # A 404 page still has a <title>, so never turn it into metadata
+        if r.status_code != 200:
+            sys.exit(
+                "book page returned " + str(r.status_code) + " for " + args.title
+            )
+        info_c = extract_info_from_html(r.content, aliases)
+    if args.require_title and not has_title(info_c):
+        sys.exit("title not found for " + str(args.title or args.from_epub))
+    if extra:
+        info_c["extra"] = extra
+    print(json.dumps(info_c, indent=4))
 
 
-content = requests.get("https://english-e-reader.net/book/" + args.title).content
-info_c = extract_info_from_html(content)
-print(json.dumps(info_c, indent=4))
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,43 @@
+import unittest
+
+from diff_metadata import diff_metadata, format_change
+
+METADATA = {
+    "title": "The Ring - Bernard Smith - English e-Reader",
+    "level": "Intermediate 1",
+    "author": "Bernard Smith",
+    "description": "A story about a ring.",
+    "tags": ["crime", "thriller"],
+}
+
+
+class DiffMetadataTest(unittest.TestCase):
+    def test_identical(self):
+        self.assertEqual(diff_metadata(METADATA, dict(METADATA)), [])
+
+    def test_changed_level(self):
+        new = dict(METADATA, level="Intermediate 2")
+        changes = diff_metadata(METADATA, new)
+        self.assertEqual(
+            changes,
+            [{"field": "level", "old": "Intermediate 1", "new": "Intermediate 2"}],
+        )
+        self.assertEqual(
+            format_change(changes[0]), "level: 'Intermediate 1' -> 'Intermediate 2'"
+        )
+
+    def test_tags_added_and_removed(self):
+        new = dict(METADATA, tags=["thriller", "mystery"])
+        changes = diff_metadata(METADATA, new)
+        self.assertEqual(
+            changes, [{"field": "tags", "added": ["mystery"], "removed": ["crime"]}]
+        )
+        self.assertEqual(format_change(changes[0]), "tags: +mystery -crime")
+
+    def test_reordered_tags(self):
+        new = dict(METADATA, tags=["thriller", "crime"])
+        self.assertEqual(diff_metadata(METADATA, new), [])
+
+
+if __name__ == "__main__":
+    unittest.main()
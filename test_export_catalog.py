@@ -0,0 +1,84 @@
+import csv
+import json
+import os
+import tempfile
+import unittest
+
+from export_catalog import build_catalog, write_catalog
+
+
+class ExportCatalogTest(unittest.TestCase):
+    def setUp(self):
+        tmp = tempfile.TemporaryDirectory()
+        self.addCleanup(tmp.cleanup)
+        self.root = tmp.name
+        self.write_book(
+            "the-ring-bernard-smith",
+            {
+                "title": "The Ring",
+                "author": "Bernard Smith",
+                "level": "Intermediate 1",
+                "tags": ["crime", "thriller"],
+            },
+        )
+        self.write_book(
+            "psycho-robert-bloch",
+            {
+                "title": "Psycho",
+                "author": "Robert Bloch",
+                "level": "Intermediate 2",
+                "tags": ["horror"],
+            },
+        )
+        # a directory without metadata.json is not a book
+        os.makedirs(os.path.join(self.root, "luke"))
+
+    def write_book(self, slug, metadata):
+        os.makedirs(os.path.join(self.root, slug, slug + "_splitted"))
+        with open(os.path.join(self.root, slug, "metadata.json"), "w") as file:
+            json.dump(metadata, file)
+
+    def test_build_catalog(self):
+        self.assertEqual(
+            build_catalog(self.root),
+            [
+                {
+                    "title": "Psycho",
+                    "author": "Robert Bloch",
+                    "level": "Intermediate 2",
+                    "tags": ["horror"],
+                    "slug": "psycho-robert-bloch",
+                    "path": "psycho-robert-bloch",
+                },
+                {
+                    "title": "The Ring",
+                    "author": "Bernard Smith",
+                    "level": "Intermediate 1",
+                    "tags": ["crime", "thriller"],
+                    "slug": "the-ring-bernard-smith",
+                    "path": "the-ring-bernard-smith",
+                },
+            ],
+        )
+
+    def test_write_csv(self):
+        out = os.path.join(self.root, "catalog.csv")
+        write_catalog(build_catalog(self.root), out)
+        with open(out, newline="") as file:
+            rows = list(csv.DictReader(file))
+        self.assertEqual(
+            [row["slug"] for row in rows],
+            ["psycho-robert-bloch", "the-ring-bernard-smith"],
+        )
+        self.assertEqual(rows[1]["tags"], "crime;thriller")
+
+    def test_write_json(self):
+        out = os.path.join(self.root, "catalog.json")
+        catalog = build_catalog(self.root)
+        write_catalog(catalog, out)
+        with open(out) as file:
+            self.assertEqual(json.load(file), catalog)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,276 @@
+import io
+import json
+import os
+import tempfile
+import unittest
+import zipfile
+from contextlib import redirect_stdout
+from unittest import mock
+
+from fetch_meta_data import (
+    extract_headwords,
+    extract_info_from_html,
+    extract_word_count,
+    has_title,
+    level_mapping,
+    load_tag_aliases,
+    main,
+    map_english_levels,
+    metadata_from_epub,
+    normalize_tags,
+    parse_extra,
+)
+
+EMPTY_HTML = b"<html></html>"
+
+
+def fake_page(status_code, content):
+    return mock.patch(
+        "fetch_meta_data.requests.get",
+        return_value=mock.Mock(status_code=status_code, content=content),
+    )
+
+
+class HasTitleTest(unittest.TestCase):
+    def test_title(self):
+        self.assertTrue(has_title({"title": "Dangerous Game - Harris William"}))
+
+    def test_missing_title(self):
+        self.assertFalse(has_title({"title": "Title not found"}))
+        self.assertFalse(has_title({"title": ""}))
+
+    def test_empty_html_has_no_title(self):
+        self.assertFalse(has_title(extract_info_from_html(EMPTY_HTML)))
+
+
+class MainTest(unittest.TestCase):
+    def test_require_title_exits_on_empty_html(self):
+        with fake_page(200, EMPTY_HTML), redirect_stdout(io.StringIO()) as out:
+            with self.assertRaises(SystemExit) as cm:
+                main(["-t", "missing-book", "--require-title"])
+        self.assertEqual(cm.exception.code, "title not found for missing-book")
+        self.assertEqual(out.getvalue(), "")
+
+    def test_empty_html_without_require_title(self):
+        with fake_page(200, EMPTY_HTML), redirect_stdout(io.StringIO()) as out:
+            main(["-t", "missing-book"])
+        self.assertEqual(json.loads(out.getvalue())["title"], "Title not found")
+
+    def test_exits_on_non_200_page(self):
+        with fake_page(404, b"<title>Not found</title>"):
+            with redirect_stdout(io.StringIO()) as out:
+                with self.assertRaises(SystemExit) as cm:
+                    main(["-t", "missing-book"])
+        self.assertEqual(cm.exception.code, "book page returned 404 for missing-book")
+        self.assertEqual(out.getvalue(), "")
+
+
+class ExtraMetadataTest(unittest.TestCase):
+    def test_parse_extra(self):
+        self.assertEqual(
+            parse_extra(["library_id=42", "note=bought in 2024", "empty="]),
+            {"library_id": "42", "note": "bought in 2024", "empty": ""},
+        )
+
+    def test_parse_extra_rejects_malformed(self):
+        for pairs in [["library_id"], ["=42"], ["a=1", "a=2"]]:
+            with self.subTest(pairs=pairs), self.assertRaises(ValueError):
+                parse_extra(pairs)
+
+    def test_extra_in_output(self):
+        argv = ["-t", "the-ring", "--extra", "library_id=42", "--extra", "note=x=y"]
+        with fake_page(200, b"<title>The Ring</title>"):
+            with redirect_stdout(io.StringIO()) as out:
+                main(argv)
+        info = json.loads(out.getvalue())
+        self.assertEqual(info["extra"], {"library_id": "42", "note": "x=y"})
+        self.assertEqual(info["title"], "The Ring")
+
+    def test_no_extra_key_by_default(self):
+        with fake_page(200, b"<title>The Ring</title>"):
+            with redirect_stdout(io.StringIO()) as out:
+                main(["-t", "the-ring"])
+        self.assertNotIn("extra", json.loads(out.getvalue()))
+
+
+class LevelMappingTest(unittest.TestCase):
+    def test_level_mapping(self):
+        self.assertEqual(
+            level_mapping(),
+            {
+                "A1 Starter": "Beginner 1",
+                "A2 Elementary": "Beginner 2",
+                "B1 Pre-Intermediate": "Intermediate 1",
+                "B1+ Intermediate": "Intermediate 1",
+                "B2 Intermediate-Plus": "Intermediate 2",
+                "B2+ Upper-Intermediate": "Intermediate 2",
+                "C1 Advanced": "Advanced 1",
+                "C2 Unabridged": "Advanced 2",
+            },
+        )
+
+    def test_level_mapping_returns_copy(self):
+        mapping = level_mapping()
+        mapping["A1 Starter"] = "Advanced 2"
+        del mapping["C1 Advanced"]
+        self.assertEqual(level_mapping()["A1 Starter"], "Beginner 1")
+        self.assertIn("C1 Advanced", level_mapping())
+        self.assertEqual(map_english_levels("A1 Starter"), "Beginner 1")
+        self.assertEqual(map_english_levels("C1 Advanced"), "Advanced 1")
+
+
+class TagAliasesTest(unittest.TestCase):
+    def write_aliases(self, content):
+        fd, path = tempfile.mkstemp(suffix=".json")
+        with os.fdopen(fd, "w") as file:
+            file.write(content)
+        self.addCleanup(os.remove, path)
+        return path
+
+    def test_normalize_tags(self):
+        aliases = {"sci-fi": "science fiction", "thriller": "crime"}
+        tags = ["Sci-Fi", "science fiction", "drama", "thriller"]
+        self.assertEqual(
+            normalize_tags(tags, aliases), ["science fiction", "drama", "crime"]
+        )
+
+    def test_normalize_tags_drops_duplicates_ignoring_case(self):
+        aliases = {"sci-fi": "science fiction"}
+        self.assertEqual(
+            normalize_tags(["Sci-Fi", "Science Fiction", "Drama", "drama"], aliases),
+            ["science fiction", "Drama"],
+        )
+
+    def test_aliases_applied_while_parsing(self):
+        html = (
+            b"<title>The Ring</title>"
+            b'<span class="label label-default">Sci-Fi</span>'
+            b'<span class="label label-default">Science Fiction</span>'
+            b'<span class="label label-default">Drama</span>'
+        )
+        aliases = {"sci-fi": "science fiction"}
+        info = extract_info_from_html(html, aliases)
+        self.assertEqual(info["tags"], ["science fiction", "Drama"])
+        info = extract_info_from_html(html)
+        self.assertEqual(info["tags"], ["Sci-Fi", "Science Fiction", "Drama"])
+
+    def test_load_tag_aliases(self):
+        path = self.write_aliases(json.dumps({"Sci-Fi": "science fiction"}))
+        self.assertEqual(load_tag_aliases(path), {"sci-fi": "science fiction"})
+
+    def test_load_tag_aliases_rejects_non_string_values(self):
+        for content in ['["sci-fi"]', '{"sci-fi": 1}', '{"sci-fi": ["a"]}']:
+            path = self.write_aliases(content)
+            with self.assertRaises(ValueError):
+                load_tag_aliases(path)
+
+    def test_load_tag_aliases_rejects_bad_json(self):
+        path = self.write_aliases("{sci-fi")
+        with self.assertRaises(ValueError):
+            load_tag_aliases(path)
+
+
+class WordCountTest(unittest.TestCase):
+    def test_extract_word_count(self):
+        cases = [
+            (b"<p>\nwords: 22,853\n</p>", 22853),
+            ("words: 1,234,567", 1234567),
+            ("words: 900", 900),
+            ("no statistics here", 0),
+            ("words: ,", 0),
+            ("words: ,,,", 0),
+        ]
+        for html, want in cases:
+            with self.subTest(html=html):
+                self.assertEqual(extract_word_count(html), want)
+
+    def test_extract_headwords(self):
+        cases = [
+            (b"<p>\nheadwords: 1,200\n</p>", 1200),
+            ("headwords: 600", 600),
+            ("words: 22,853", 0),
+            ("no statistics here", 0),
+            ("headwords: ,", 0),
+        ]
+        for html, want in cases:
+            with self.subTest(html=html):
+                self.assertEqual(extract_headwords(html), want)
+
+    def test_counts_in_metadata(self):
+        html = b"<title>The Ring</title><pre>\nwords: 18,685\nheadwords: 1,200\n</pre>"
+        info = extract_info_from_html(html)
+        self.assertEqual(info["word_count"], 18685)
+        self.assertEqual(info["headwords"], 1200)
+
+
+CONTAINER_XML = """<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf"
+              media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>"""
+
+CONTENT_OPF = """<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>The Ring</dc:title>
+    <dc:creator>Bernard Smith</dc:creator>
+    <dc:language>en</dc:language>
+    <dc:subject>crime</dc:subject>
+    <dc:subject>thriller</dc:subject>
+    <dc:description>A story about a ring.</dc:description>
+  </metadata>
+</package>"""
+
+
+class MetadataFromEpubTest(unittest.TestCase):
+    def write_epub(self, entries):
+        fd, path = tempfile.mkstemp(suffix=".epub")
+        os.close(fd)
+        self.addCleanup(os.remove, path)
+        with zipfile.ZipFile(path, "w") as epub_file:
+            epub_file.writestr("mimetype", "application/epub+zip")
+            for name, content in entries.items():
+                epub_file.writestr(name, content)
+        return path
+
+    def test_metadata_from_epub(self):
+        path = self.write_epub(
+            {"META-INF/container.xml": CONTAINER_XML, "OEBPS/content.opf": CONTENT_OPF}
+        )
+        self.assertEqual(
+            metadata_from_epub(path),
+            {
+                "title": "The Ring",
+                "author": "Bernard Smith",
+                "language": "en",
+                "description": "A story about a ring.",
+                "tags": ["crime", "thriller"],
+            },
+        )
+
+    def test_missing_container(self):
+        path = self.write_epub({"OEBPS/content.opf": CONTENT_OPF})
+        with self.assertRaises(KeyError):
+            metadata_from_epub(path)
+
+    def test_main_from_epub(self):
+        path = self.write_epub(
+            {"META-INF/container.xml": CONTAINER_XML, "OEBPS/content.opf": CONTENT_OPF}
+        )
+        with redirect_stdout(io.StringIO()) as out:
+            main(["--from-epub", path, "--extra", "library_id=42"])
+        info = json.loads(out.getvalue())
+        self.assertEqual(info["title"], "The Ring")
+        self.assertEqual(info["extra"], {"library_id": "42"})
+
+    def test_main_from_broken_epub(self):
+        path = self.write_epub({})
+        with self.assertRaises(SystemExit) as cm:
+            main(["--from-epub", path])
+        self.assertIn("cannot read metadata from", cm.exception.code)
+
+
+if __name__ == "__main__":
+    unittest.main()